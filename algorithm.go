@@ -0,0 +1,20 @@
+package fastlimiter
+
+// Algorithm selects the rate limiting strategy a FastLimiter uses.
+type Algorithm int
+
+const (
+	// AlgorithmFixedWindow resets the counter to zero at the start of every window. It is
+	// the default and is driven entirely through Get.
+	AlgorithmFixedWindow Algorithm = iota
+	// AlgorithmSlidingWindow blends the previous and current window's counts, weighted by
+	// how far into the current window the request landed, to smooth bursts at window
+	// boundaries. It is driven through Get.
+	AlgorithmSlidingWindow
+	// AlgorithmTokenBucket refills tokens continuously at a rate of Total/Duration. It is
+	// driven through Wait and Reserve rather than Get.
+	AlgorithmTokenBucket
+	// AlgorithmLeakyBucket is reserved for a future leaky-bucket implementation; selecting
+	// it makes Get return an error.
+	AlgorithmLeakyBucket
+)
@@ -0,0 +1,17 @@
+package fastlimiter
+
+// Backend stores and atomically updates the counters a FastLimiter checks on every Get.
+// MemoryBackend is the zero-dependency default; RedisBackend shares counters across
+// multiple app instances behind a load balancer.
+type Backend interface {
+	// IncrementIfAllowed applies policy to key, starting a new window or escalating to the
+	// next policy tier when the current one has expired, and returns the counter after
+	// accounting for this request.
+	IncrementIfAllowed(key string, policy ...int32) (*limiterCacheItem, error)
+	// Delete removes any state tracked for key.
+	Delete(key string)
+	// Cleanup evicts expired entries.
+	Cleanup()
+	// Count returns the number of keys currently tracked.
+	Count() int
+}
@@ -1,8 +1,10 @@
 package fastlimiter_test
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -340,6 +342,110 @@ func TestFastlimiter(t *testing.T) {
 
 		assert.Equal("fastlimiter: must be positive integer", err3.Error())
 	})
+
+	t.Run("Fastlimiter with sliding window should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{Algorithm: fastlimiter.AlgorithmSlidingWindow})
+
+		id := genID()
+		policy := []int{10, 200}
+
+		for i := 0; i < 10; i++ {
+			res, err := limiter.Get(id, policy...)
+			assert.Nil(err)
+			assert.Equal(10, res.Total)
+			assert.True(res.Remaining >= 0)
+		}
+		res, err := limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(-1, res.Remaining)
+
+		// Sleeping past the window boundary should still count most of the previous
+		// window's usage, unlike a fixed window which would reset to 9 remaining.
+		time.Sleep(210 * time.Millisecond)
+		res, err = limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.True(res.Remaining >= 0)
+		assert.True(res.Remaining < 9)
+
+		// A long enough idle period drops the previous window entirely.
+		time.Sleep(410 * time.Millisecond)
+		res, err = limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(9, res.Remaining)
+	})
+
+	t.Run("Fastlimiter with token bucket should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{Algorithm: fastlimiter.AlgorithmTokenBucket})
+
+		id := genID()
+		policy := []int{2, 1000}
+
+		res1, err := limiter.Reserve(id, policy...)
+		assert.Nil(err)
+		assert.True(res1.OK())
+		assert.Equal(time.Duration(0), res1.Delay())
+
+		res2, err := limiter.Reserve(id, policy...)
+		assert.Nil(err)
+		assert.True(res2.OK())
+
+		res3, err := limiter.Reserve(id, policy...)
+		assert.Nil(err)
+		assert.False(res3.OK())
+		assert.True(res3.Delay() > 0)
+
+		// Canceling res1 frees up the token it held, but res3's own reservation is still
+		// outstanding, so the very next reservation still has to wait behind it.
+		res1.Cancel()
+		res4, err := limiter.Reserve(id, policy...)
+		assert.Nil(err)
+		assert.False(res4.OK())
+		assert.True(res4.Delay() > 0)
+
+		res3.Cancel()
+		res4.Cancel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		err = limiter.Wait(ctx, id, policy...)
+		assert.Equal(context.DeadlineExceeded, err)
+
+		_, err = limiter.Get(id, policy...)
+		assert.NotNil(err)
+	})
+
+	t.Run("Fastlimiter with Shards option should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{Shards: 4})
+
+		id := genID()
+		policy := []int{10, 1000}
+
+		res, err := limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(10, res.Total)
+		assert.Equal(9, res.Remaining)
+		assert.Equal(1, limiter.Count())
+	})
+}
+
+func BenchmarkFastlimiterBigGoroutine(b *testing.B) {
+	for _, shards := range []int{1, 32} {
+		shards := shards
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			limiter := fastlimiter.New(fastlimiter.Options{Shards: shards})
+			policy := []int{1000, 1000}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					limiter.Get(genID(), policy...)
+				}
+			})
+		})
+	}
 }
 
 // ------Helpers for help test --------
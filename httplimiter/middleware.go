@@ -0,0 +1,95 @@
+// Package httplimiter provides net/http middleware for fastlimiter.FastLimiter.
+package httplimiter
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mushroomsir/fastlimiter"
+)
+
+// KeyFunc extracts the rate-limit key for an incoming request.
+type KeyFunc func(*http.Request) string
+
+// MiddlewareOptions configures Middleware.
+type MiddlewareOptions struct {
+	// KeyFunc derives the rate-limit key from a request. Defaults to the request's remote IP.
+	KeyFunc KeyFunc
+	// Policy is passed through to FastLimiter.Get as-is; nil uses the limiter's default policy.
+	Policy []int
+	// OnLimited, if set, is called instead of the default 429 response when a request is denied.
+	OnLimited func(w http.ResponseWriter, r *http.Request, res fastlimiter.Result)
+	// IETFHeaders switches from the legacy X-RateLimit-* headers to the IETF draft
+	// RateLimit-Limit/RateLimit-Remaining/RateLimit-Reset headers.
+	IETFHeaders bool
+}
+
+// Middleware returns net/http middleware that rate limits requests through l according to opts.
+func Middleware(l *fastlimiter.FastLimiter, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = remoteIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := l.Get(keyFunc(r), opts.Policy...)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			setHeaders(w.Header(), res, opts.IETFHeaders)
+
+			if res.Remaining < 0 {
+				w.Header().Set("Retry-After", strconv.FormatInt(retryAfterSeconds(res), 10))
+				if opts.OnLimited != nil {
+					opts.OnLimited(w, r, res)
+					return
+				}
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MiddlewareFunc adapts next through Middleware in one call.
+func MiddlewareFunc(l *fastlimiter.FastLimiter, opts MiddlewareOptions, next http.HandlerFunc) http.Handler {
+	return Middleware(l, opts)(next)
+}
+
+func setHeaders(h http.Header, res fastlimiter.Result, ietf bool) {
+	remaining := res.Remaining
+	if remaining < 0 {
+		remaining = 0
+	}
+	if ietf {
+		// The IETF draft defines RateLimit-Reset as delta-seconds until the window
+		// resets, unlike the legacy header below which is an absolute epoch timestamp.
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Total))
+		h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("RateLimit-Reset", strconv.FormatInt(retryAfterSeconds(res), 10))
+		return
+	}
+	h.Set("X-RateLimit-Limit", strconv.Itoa(res.Total))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(res.Reset.Unix(), 10))
+}
+
+func retryAfterSeconds(res fastlimiter.Result) int64 {
+	after := int64(time.Until(res.Reset) / time.Second)
+	if after < 0 {
+		after = 0
+	}
+	return after
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,104 @@
+package httplimiter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/mushroomsir/fastlimiter"
+	"github.com/mushroomsir/fastlimiter/httplimiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Middleware with allowed request should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{})
+		handler := httplimiter.Middleware(limiter, httplimiter.MiddlewareOptions{
+			Policy: []int{2, 1000},
+		})(okHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(http.StatusOK, w.Code)
+		assert.Equal("2", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal("1", w.Header().Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("Middleware with denied request should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{})
+		handler := httplimiter.Middleware(limiter, httplimiter.MiddlewareOptions{
+			Policy: []int{1, 1000},
+		})(okHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.2:1234"
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(http.StatusTooManyRequests, w.Code)
+		assert.Equal("0", w.Header().Get("X-RateLimit-Remaining"))
+
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		assert.Nil(err)
+		assert.True(retryAfter >= 0)
+	})
+
+	t.Run("Middleware with IETFHeaders should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{})
+		handler := httplimiter.Middleware(limiter, httplimiter.MiddlewareOptions{
+			Policy:      []int{5, 1000},
+			IETFHeaders: true,
+		})(okHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.3:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal("5", w.Header().Get("RateLimit-Limit"))
+		assert.Equal("4", w.Header().Get("RateLimit-Remaining"))
+		assert.Empty(w.Header().Get("X-RateLimit-Limit"))
+
+		// RateLimit-Reset is delta-seconds, not the absolute epoch the legacy header uses.
+		reset, err := strconv.Atoi(w.Header().Get("RateLimit-Reset"))
+		assert.Nil(err)
+		assert.True(reset >= 0 && reset <= 1)
+	})
+
+	t.Run("Middleware with OnLimited should be", func(t *testing.T) {
+		assert := assert.New(t)
+		limiter := fastlimiter.New(fastlimiter.Options{})
+		called := false
+		handler := httplimiter.Middleware(limiter, httplimiter.MiddlewareOptions{
+			Policy: []int{1, 1000},
+			OnLimited: func(w http.ResponseWriter, r *http.Request, res fastlimiter.Result) {
+				called = true
+				w.WriteHeader(http.StatusTeapot)
+			},
+		})(okHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.4:1234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.True(called)
+		assert.Equal(http.StatusTeapot, w.Code)
+	})
+}
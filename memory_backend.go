@@ -0,0 +1,165 @@
+package fastlimiter
+
+import (
+	"hash/fnv"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memoryShard holds one slice of MemoryBackend's keyspace behind its own lock, so a
+// cleanup sweep or a hot key in one shard never stalls requests hashing to another.
+type memoryShard struct {
+	lock   sync.RWMutex
+	store  map[string]*limiterCacheItem
+	status map[string]*statusCacheItem
+}
+
+// MemoryBackend is the default, in-process Backend. It shards its keyspace across N
+// independent maps, each guarded by its own lock, to avoid a single global lock becoming
+// a bottleneck under heavy concurrent use.
+type MemoryBackend struct {
+	shards []*memoryShard
+}
+
+// NewMemoryBackend creates a MemoryBackend with shards shards. A value <= 0 uses
+// runtime.GOMAXPROCS(0)*4.
+func NewMemoryBackend(shards int) *MemoryBackend {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0) * 4
+	}
+	b := &MemoryBackend{shards: make([]*memoryShard, shards)}
+	for i := range b.shards {
+		b.shards[i] = &memoryShard{
+			store:  make(map[string]*limiterCacheItem),
+			status: make(map[string]*statusCacheItem),
+		}
+	}
+	return b
+}
+
+func (b *MemoryBackend) shardFor(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return b.shards[h.Sum32()%uint32(len(b.shards))]
+}
+
+//Delete ...
+func (b *MemoryBackend) Delete(key string) {
+	statusKey := "{" + key + "}:S"
+	s := b.shardFor(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.store, key)
+	delete(s.status, statusKey)
+}
+
+//Cleanup ...
+func (b *MemoryBackend) Cleanup() {
+	now := time.Now()
+	for _, s := range b.shards {
+		s.lock.Lock()
+		for key, value := range s.store {
+			if value.Expire.Before(now) {
+				statusKey := "{" + key + "}:S"
+				delete(s.store, key)
+				delete(s.status, statusKey)
+			}
+		}
+		s.lock.Unlock()
+	}
+}
+
+//Count ...
+func (b *MemoryBackend) Count() int {
+	total := 0
+	for _, s := range b.shards {
+		s.lock.RLock()
+		total += len(s.store)
+		s.lock.RUnlock()
+	}
+	return total
+}
+
+//IncrementIfAllowed ...
+func (b *MemoryBackend) IncrementIfAllowed(key string, policy ...int32) (*limiterCacheItem, error) {
+	s := b.shardFor(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if res, ok := s.store[key]; ok {
+		if res.Expire.Before(time.Now()) {
+			return s.newCacheItem(key, policy...), nil
+		}
+		if atomic.LoadInt32(&res.Remaining) == -1 {
+			return res, nil
+		}
+		atomic.AddInt32(&res.Remaining, -1)
+		return res, nil
+	}
+	return s.newCacheItem(key, policy...), nil
+}
+
+func (s *memoryShard) newCacheItem(key string, args ...int32) *limiterCacheItem {
+	policyCount := int32(len(args) / 2)
+	if policyCount < 2 {
+		return s.singlePolicyItem(key, args...)
+	}
+	return s.multiPolicyItem(key, args...)
+}
+
+func (s *memoryShard) singlePolicyItem(key string, args ...int32) *limiterCacheItem {
+	total := args[0]
+	duration := args[1]
+	res := &limiterCacheItem{
+		Total:     total,
+		Remaining: total - 1,
+		Duration:  time.Duration(duration) * time.Millisecond,
+		Expire:    time.Now().Add(time.Duration(duration) * time.Millisecond),
+	}
+	s.store[key] = res
+	return res
+}
+
+func (s *memoryShard) multiPolicyItem(key string, args ...int32) *limiterCacheItem {
+	policyCount := int32(len(args) / 2)
+	statusKey := "{" + key + "}:S"
+	var index int32 = 1
+
+	statusItem, ok := s.status[statusKey]
+	if !ok {
+		statusItem = &statusCacheItem{
+			Index:  1,
+			Expire: time.Now().Add(time.Duration(args[1]) * time.Millisecond * 2),
+		}
+		res := s.singlePolicyItem(key, args...)
+		s.status[statusKey] = statusItem
+		return res
+	}
+	if statusItem.Expire.Before(time.Now()) {
+		index = 1
+	} else {
+		index = statusItem.Index
+		if index >= policyCount {
+			index = policyCount
+		} else {
+			index++
+		}
+	}
+	total := args[(index*2)-2]
+	duration := args[(index*2)-1]
+
+	s.status[statusKey] = &statusCacheItem{
+		Index:  index,
+		Expire: time.Now().Add(time.Duration(duration) * time.Millisecond * 2),
+	}
+	res := &limiterCacheItem{
+		Total:     total,
+		Remaining: total - 1,
+		Duration:  time.Duration(duration) * time.Millisecond,
+		Expire:    time.Now().Add(time.Duration(duration) * time.Millisecond),
+	}
+	s.store[key] = res
+	return res
+}
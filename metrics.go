@@ -0,0 +1,20 @@
+package fastlimiter
+
+import "time"
+
+// Metrics receives instrumentation events from a FastLimiter. Implement it to export
+// request counts and latencies to a monitoring system; Options.Metrics defaults to a
+// no-op implementation.
+type Metrics interface {
+	// ObserveGet is called after every Get, with how long it took and whether the request
+	// was allowed.
+	ObserveGet(duration time.Duration, allowed bool)
+	// SetKeysTracked reports the number of keys the limiter currently tracks, as returned by
+	// FastLimiter.Count: backend keys plus any sliding-window or token-bucket keys.
+	SetKeysTracked(count int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveGet(time.Duration, bool) {}
+func (noopMetrics) SetKeysTracked(int)             {}
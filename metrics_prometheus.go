@@ -0,0 +1,52 @@
+package fastlimiter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PromMetrics is a Metrics implementation backed by Prometheus collectors. Pass one to
+// Options.Metrics to expose fastlimiter_requests_total, fastlimiter_get_duration_seconds
+// and fastlimiter_keys_tracked.
+type PromMetrics struct {
+	requests *prometheus.CounterVec
+	duration prometheus.Histogram
+	keys     prometheus.Gauge
+}
+
+// NewPromMetrics creates a PromMetrics and registers its collectors with reg. Pass
+// prometheus.DefaultRegisterer to use the global registry.
+func NewPromMetrics(reg prometheus.Registerer) *PromMetrics {
+	m := &PromMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "fastlimiter_requests_total",
+			Help: "Total number of rate limit checks, labeled by result.",
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "fastlimiter_get_duration_seconds",
+			Help: "Latency of FastLimiter.Get calls.",
+		}),
+		keys: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "fastlimiter_keys_tracked",
+			Help: "Number of keys currently tracked by the limiter.",
+		}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.keys)
+	return m
+}
+
+//ObserveGet ...
+func (m *PromMetrics) ObserveGet(duration time.Duration, allowed bool) {
+	result := "denied"
+	if allowed {
+		result = "allowed"
+	}
+	m.requests.WithLabelValues(result).Inc()
+	m.duration.Observe(duration.Seconds())
+}
+
+//SetKeysTracked ...
+func (m *PromMetrics) SetKeysTracked(count int) {
+	m.keys.Set(float64(count))
+}
@@ -0,0 +1,178 @@
+package fastlimiter
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// incrementScript atomically increments the counter at KEYS[1], sets its expiry to ARGV[1]
+// milliseconds on the first increment, and returns the new count alongside the remaining
+// TTL in milliseconds, so a single round trip is enough to rebuild a limiterCacheItem. Once
+// the counter reaches ARGV[2] (the policy's Total) it stops incrementing, so a sustained
+// flood of denied requests doesn't grow the key without bound.
+const incrementScript = `
+local total = tonumber(ARGV[2])
+local current = tonumber(redis.call("GET", KEYS[1]))
+if current and current >= total then
+	return {current + 1, redis.call("PTTL", KEYS[1])}
+end
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+return {count, ttl}
+`
+
+// RedisBackend is a Backend that stores counters in Redis, so a FastLimiter can be shared
+// across multiple app instances behind a load balancer. The counter key and its status key
+// share the "{key}:S" hash tag so both land on the same slot in cluster mode.
+type RedisBackend struct {
+	client     *redis.Client
+	keyPattern string
+}
+
+// NewRedisBackend wraps client. keyPrefix should match Options.Prefix and is used only to
+// scope the keyspace scan Count performs.
+func NewRedisBackend(client *redis.Client, keyPrefix string) *RedisBackend {
+	return &RedisBackend{client: client, keyPattern: keyPrefix + "*"}
+}
+
+//Delete ...
+func (b *RedisBackend) Delete(key string) {
+	statusKey := "{" + key + "}:S"
+	b.client.Del(key, statusKey)
+}
+
+// Cleanup is a no-op: Redis expires counter and status keys via the TTLs set by
+// IncrementIfAllowed, so there is nothing left to sweep.
+func (b *RedisBackend) Cleanup() {}
+
+//Count ...
+func (b *RedisBackend) Count() int {
+	count := 0
+	var cursor uint64
+	for {
+		keys, next, err := b.client.Scan(cursor, b.keyPattern, 100).Result()
+		if err != nil {
+			return count
+		}
+		for _, key := range keys {
+			if !strings.HasSuffix(key, "}:S") {
+				count++
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return count
+}
+
+//IncrementIfAllowed ...
+func (b *RedisBackend) IncrementIfAllowed(key string, policy ...int32) (*limiterCacheItem, error) {
+	policyCount := int32(len(policy) / 2)
+	total := policy[0]
+	duration := policy[1]
+
+	if policyCount >= 2 {
+		// Only escalate the tier when the counter key's window has actually expired
+		// (mirrors MemoryBackend, which re-resolves the tier solely when it must create a
+		// fresh cache item). A plain GET+INCR can't see that atomically, so there is a
+		// narrow race on the very first request of a new window; an escalation script
+		// would close it but is not worth the complexity here.
+		exists, err := b.client.Exists(key).Result()
+		if err != nil {
+			return nil, err
+		}
+		var index int32
+		if exists == 1 {
+			index, err = b.currentIndex(key)
+		} else {
+			index, err = b.advanceIndex(key, policyCount, policy)
+		}
+		if err != nil {
+			return nil, err
+		}
+		total = policy[(index*2)-2]
+		duration = policy[(index*2)-1]
+	}
+
+	count, ttl, err := b.increment(key, duration, total)
+	if err != nil {
+		return nil, err
+	}
+	remaining := total - int32(count)
+	if remaining < -1 {
+		// Mirror MemoryBackend, which never reports less than -1 once a key is denied.
+		remaining = -1
+	}
+	return &limiterCacheItem{
+		Total:     total,
+		Remaining: remaining,
+		Duration:  time.Duration(duration) * time.Millisecond,
+		Expire:    time.Now().Add(ttl),
+	}, nil
+}
+
+// currentIndex returns the tier index that is already active for key's window, without
+// advancing it.
+func (b *RedisBackend) currentIndex(key string) (int32, error) {
+	statusKey := "{" + key + "}:S"
+	val, err := b.client.Get(statusKey).Int64()
+	if err == redis.Nil {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int32(val), nil
+}
+
+// advanceIndex mirrors MemoryBackend's status-key escalation logic, storing the index in
+// Redis instead of an in-process map. It is only called when key's previous window has
+// expired, so it is safe to move to the next tier.
+func (b *RedisBackend) advanceIndex(key string, policyCount int32, policy []int32) (int32, error) {
+	statusKey := "{" + key + "}:S"
+
+	val, err := b.client.Get(statusKey).Int64()
+	if err == redis.Nil {
+		if err := b.client.Set(statusKey, 1, time.Duration(policy[1])*time.Millisecond*2).Err(); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	index := int32(val)
+	if index >= policyCount {
+		index = policyCount
+	} else {
+		index++
+	}
+	duration := policy[(index*2)-1]
+	if err := b.client.Set(statusKey, index, time.Duration(duration)*time.Millisecond*2).Err(); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+func (b *RedisBackend) increment(key string, durationMs, total int32) (int64, time.Duration, error) {
+	res, err := b.client.Eval(incrementScript, []string{key}, durationMs, total).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	vals, ok := res.([]interface{})
+	if !ok || len(vals) != 2 {
+		return 0, 0, errors.New("fastlimiter: unexpected response from redis")
+	}
+	count, _ := vals[0].(int64)
+	ttlMs, _ := vals[1].(int64)
+	return count, time.Duration(ttlMs) * time.Millisecond, nil
+}
@@ -0,0 +1,84 @@
+package fastlimiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/mushroomsir/fastlimiter"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisBackend(t *testing.T) (*fastlimiter.RedisBackend, *redis.Client) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+	if err := client.Ping().Err(); err != nil {
+		t.Skipf("redis not available at 127.0.0.1:6379: %v", err)
+	}
+	return fastlimiter.NewRedisBackend(client, "limit:"), client
+}
+
+func TestRedisBackend(t *testing.T) {
+	t.Run("RedisBackend with default Options should be", func(t *testing.T) {
+		assert := assert.New(t)
+		backend, client := newTestRedisBackend(t)
+		defer client.Close()
+
+		limiter := fastlimiter.New(fastlimiter.Options{Backend: backend})
+		id := genID()
+		defer limiter.Remove(id)
+
+		policy := []int{10, 1000}
+		res, err := limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(10, res.Total)
+		assert.Equal(9, res.Remaining)
+
+		res, err = limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(8, res.Remaining)
+	})
+
+	t.Run("RedisBackend with multi-policy should be", func(t *testing.T) {
+		assert := assert.New(t)
+		backend, client := newTestRedisBackend(t)
+		defer client.Close()
+
+		limiter := fastlimiter.New(fastlimiter.Options{Backend: backend})
+		id := genID()
+		defer limiter.Remove(id)
+
+		policy := []int{2, 100, 1, 200}
+		res, err := limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(2, res.Total)
+		assert.Equal(1, res.Remaining)
+
+		res, err = limiter.Get(id, policy...)
+		assert.Equal(0, res.Remaining)
+
+		time.Sleep(res.Duration + 10*time.Millisecond)
+		res, err = limiter.Get(id, policy...)
+		assert.Nil(err)
+		assert.Equal(1, res.Total)
+		assert.Equal(0, res.Remaining)
+	})
+
+	t.Run("RedisBackend with Remove should be", func(t *testing.T) {
+		assert := assert.New(t)
+		backend, client := newTestRedisBackend(t)
+		defer client.Close()
+
+		limiter := fastlimiter.New(fastlimiter.Options{Backend: backend})
+		id := genID()
+
+		res, err := limiter.Get(id, 10, 1000)
+		assert.Nil(err)
+		assert.Equal(9, res.Remaining)
+
+		limiter.Remove(id)
+		res, err = limiter.Get(id, 10, 1000)
+		assert.Nil(err)
+		assert.Equal(9, res.Remaining)
+		limiter.Remove(id)
+	})
+}
@@ -0,0 +1,87 @@
+package fastlimiter
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// slidingWindowItem tracks the counts of the previous and current window for one key.
+// used is estimated as weight*prev + curr, where weight is how much of the previous
+// window still overlaps the sliding lookback, which smooths the hard reset a fixed
+// window takes at its boundary.
+type slidingWindowItem struct {
+	prev        int32
+	curr        int32
+	windowStart time.Time
+	duration    time.Duration
+}
+
+// getSlidingWindow implements AlgorithmSlidingWindow. Unlike the fixed-window path it does
+// not support multi-policy escalation; policy must be exactly [total, durationMs].
+func (l *FastLimiter) getSlidingWindow(key string, policy ...int32) (Result, error) {
+	if len(policy) != 2 {
+		return Result{}, errors.New("fastlimiter: sliding window requires a single total, duration policy")
+	}
+	total := policy[0]
+	duration := time.Duration(policy[1]) * time.Millisecond
+	now := time.Now()
+
+	l.slideLock.Lock()
+	defer l.slideLock.Unlock()
+
+	item, ok := l.slide[key]
+	switch {
+	case !ok || now.Sub(item.windowStart) >= duration*2:
+		// No state, or idle long enough that the previous window no longer overlaps at all.
+		item = &slidingWindowItem{windowStart: now}
+		l.slide[key] = item
+	case now.Sub(item.windowStart) >= duration:
+		item.prev = item.curr
+		item.curr = 0
+		item.windowStart = item.windowStart.Add(duration)
+	}
+	item.duration = duration
+
+	windowEnd := item.windowStart.Add(duration)
+	weight := float64(windowEnd.Sub(now)) / float64(duration)
+	used := weight*float64(item.prev) + float64(item.curr)
+
+	result := Result{
+		Total:    int(total),
+		Duration: duration,
+		Reset:    windowEnd,
+	}
+	if used >= float64(total) {
+		result.Remaining = -1
+		return result, nil
+	}
+
+	item.curr++
+	used = weight*float64(item.prev) + float64(item.curr)
+	remaining := total - int32(math.Ceil(used))
+	if remaining < 0 {
+		remaining = 0
+	}
+	result.Remaining = int(remaining)
+	return result, nil
+}
+
+func (l *FastLimiter) removeSlidingWindow(key string) {
+	l.slideLock.Lock()
+	delete(l.slide, key)
+	l.slideLock.Unlock()
+}
+
+// cleanSlidingWindow evicts keys whose previous window no longer overlaps the sliding
+// lookback at all, the same staleness threshold getSlidingWindow uses to start fresh.
+func (l *FastLimiter) cleanSlidingWindow() {
+	now := time.Now()
+	l.slideLock.Lock()
+	defer l.slideLock.Unlock()
+	for key, item := range l.slide {
+		if now.Sub(item.windowStart) >= item.duration*2 {
+			delete(l.slide, key)
+		}
+	}
+}
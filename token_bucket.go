@@ -0,0 +1,159 @@
+package fastlimiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket refills continuously at rate tokens/second, up to total.
+type tokenBucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	total      float64
+	rate       float64 // tokens per second
+	lastRefill time.Time
+}
+
+func (b *tokenBucket) refillLocked(now time.Time) {
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = math.Min(b.total, b.tokens+elapsed*b.rate)
+	b.lastRefill = now
+}
+
+// Reservation is returned by Reserve for AlgorithmTokenBucket, modeled after
+// golang.org/x/time/rate.Reservation. Reserve always takes a token, letting the bucket go
+// negative, so concurrent reservations each see a distinct, correctly queued Delay instead
+// of racing to re-check the same snapshot. If OK is false, Delay reports how long to wait
+// before that reserved token is actually available.
+type Reservation struct {
+	ok       bool
+	consumed bool
+	bucket   *tokenBucket
+	delay    time.Duration
+}
+
+// OK reports whether a token was immediately available.
+func (r *Reservation) OK() bool { return r != nil && r.ok }
+
+// Delay reports how long the caller should wait before the reserved token is available.
+// It is zero when OK is true.
+func (r *Reservation) Delay() time.Duration { return r.delay }
+
+// Cancel returns the reserved token to its bucket, undoing an OK reservation the caller
+// decided not to use after all. A denied reservation's token is already factored into the
+// Delay of every reservation made after it, so canceling one would let the bucket admit a
+// burst above rate; Cancel is a no-op for those.
+func (r *Reservation) Cancel() {
+	if r == nil || !r.consumed || !r.ok {
+		return
+	}
+	r.bucket.mu.Lock()
+	r.bucket.tokens = math.Min(r.bucket.total, r.bucket.tokens+1)
+	r.bucket.mu.Unlock()
+	r.consumed = false
+}
+
+func (l *FastLimiter) getBucket(id string, policy ...int) (*tokenBucket, error) {
+	key := l.options.Prefix + id
+
+	var total, durationMs int
+	switch len(policy) {
+	case 0:
+		total, durationMs = l.options.Max, int(l.options.Duration/time.Millisecond)
+	case 2:
+		total, durationMs = policy[0], policy[1]
+	default:
+		return nil, errors.New("fastlimiter: must be paired values")
+	}
+
+	l.bucketLock.Lock()
+	defer l.bucketLock.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{
+			tokens:     float64(total),
+			total:      float64(total),
+			rate:       float64(total) / (time.Duration(durationMs) * time.Millisecond).Seconds(),
+			lastRefill: time.Now(),
+		}
+		l.buckets[key] = b
+	}
+	return b, nil
+}
+
+// Reserve takes one token from id's bucket, reporting a Reservation. policy, if given, is
+// a single [total, durationMs] pair; otherwise the limiter's default applies. Reserve
+// always deducts the token, even when that drives the bucket negative, so every caller
+// gets its own correctly queued Delay.
+func (l *FastLimiter) Reserve(id string, policy ...int) (res *Reservation, err error) {
+	start := time.Now()
+	defer func() {
+		l.metrics.ObserveGet(time.Since(start), err == nil && res.OK())
+	}()
+
+	b, err := l.getBucket(id, policy...)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refillLocked(time.Now())
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return &Reservation{ok: true, consumed: true, bucket: b}, nil
+	}
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	return &Reservation{ok: false, consumed: true, bucket: b, delay: delay}, nil
+}
+
+// Wait blocks until id's bucket reservation's delay elapses, ctx is done, or an error
+// occurs, whichever happens first. A successful return has already consumed the token; a
+// reservation denied by ctx expiring keeps its token reserved, since it has already been
+// factored into the Delay of every reservation made after it.
+func (l *FastLimiter) Wait(ctx context.Context, id string, policy ...int) error {
+	res, err := l.Reserve(id, policy...)
+	if err != nil {
+		return err
+	}
+	if res.OK() {
+		return nil
+	}
+
+	timer := time.NewTimer(res.Delay())
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *FastLimiter) removeTokenBucket(key string) {
+	l.bucketLock.Lock()
+	delete(l.buckets, key)
+	l.bucketLock.Unlock()
+}
+
+// cleanTokenBuckets evicts buckets that have been idle long enough to have fully
+// refilled, so a stream of distinct keys does not grow l.buckets without bound.
+func (l *FastLimiter) cleanTokenBuckets() {
+	now := time.Now()
+	l.bucketLock.Lock()
+	defer l.bucketLock.Unlock()
+	for key, b := range l.buckets {
+		b.mu.Lock()
+		idleFor := now.Sub(b.lastRefill).Seconds()
+		refilled := b.tokens+idleFor*b.rate >= b.total
+		stale := refilled && idleFor >= 2*(b.total/b.rate)
+		b.mu.Unlock()
+		if stale {
+			delete(l.buckets, key)
+		}
+	}
+}